@@ -0,0 +1,77 @@
+package weather
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cacheDirName 缓存目录名称，位于os.UserCacheDir()下
+const cacheDirName = "tmux-weather-go"
+
+// cacheDir 返回缓存目录路径，不负责创建目录
+func cacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("get user cache dir failed: %w", err)
+	}
+	return filepath.Join(base, cacheDirName), nil
+}
+
+// cachePath 返回指定地址对应的缓存文件路径，文件名为地址的sha1摘要，避免多地址互相覆盖
+func cachePath(loc string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(loc))
+	return filepath.Join(dir, fmt.Sprintf("%x.json", sum)), nil
+}
+
+// loadCache 读取指定地址的缓存数据，缓存文件不存在时返回nil, nil
+func loadCache(loc string) (*Data, error) {
+	name, err := cachePath(loc)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read cache file failed, filename:%s, err:%w", name, err)
+	}
+	if len(file) == 0 {
+		return nil, nil
+	}
+	data := &Data{}
+	if err := json.Unmarshal(file, data); err != nil {
+		return nil, fmt.Errorf("unmarshal cache file failed, filename:%s, err:%w", name, err)
+	}
+	return data, nil
+}
+
+// saveCache 将数据写入指定地址对应的缓存文件，目录不存在时自动创建
+func saveCache(loc string, data *Data) error {
+	dir, err := cacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create cache dir failed, dir:%s, err:%w", dir, err)
+	}
+	name, err := cachePath(loc)
+	if err != nil {
+		return err
+	}
+	file, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal cache data failed: %w", err)
+	}
+	if err := os.WriteFile(name, file, 0644); err != nil {
+		return fmt.Errorf("write cache file failed, filename:%s, err:%w", name, err)
+	}
+	return nil
+}
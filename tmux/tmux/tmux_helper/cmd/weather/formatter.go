@@ -0,0 +1,121 @@
+package weather
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	formatCurrent  = "current"  // 当前天气状况
+	formatOneline  = "oneline"  // 单行精简格式
+	formatForecast = "forecast" // 未来几天预报
+	formatAstro    = "astro"    // 日月升降等天文信息
+)
+
+// Formatter 将归一化后的天气信息格式化为最终展示消息
+type Formatter interface {
+	// Format 根据数据生成展示消息
+	Format(data *Data) (string, error)
+}
+
+// formatters 已注册的格式化器，key为--format flag的取值
+var formatters = map[string]Formatter{
+	formatCurrent:  currentFormatter{},
+	formatOneline:  onelineFormatter{},
+	formatForecast: forecastFormatter{},
+	formatAstro:    astroFormatter{},
+}
+
+// getFormatter 根据名称获取对应的格式化器
+func getFormatter(name string) (Formatter, error) {
+	formatter, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format: %s", name)
+	}
+	return formatter, nil
+}
+
+// currentFormatter 当前温度+天气描述，与重构前的默认行为保持一致
+type currentFormatter struct{}
+
+// Format 实现Formatter接口
+func (currentFormatter) Format(data *Data) (string, error) {
+	weather := data.Weather
+	message := formatTempC(weather.TempC)
+	if weather.Description != "" {
+		message = weather.Description + " " + message
+	}
+	return message, nil
+}
+
+// onelineFormatter 紧凑单行格式：location: cond ±T°C ↑wind
+type onelineFormatter struct{}
+
+// Format 实现Formatter接口
+func (onelineFormatter) Format(data *Data) (string, error) {
+	weather := data.Weather
+	message := fmt.Sprintf("%s %s ↑%s", weather.Description, formatSignedTempC(weather.TempC), formatSpeed(weather.WindspeedKmph))
+	if weather.Location != "" {
+		message = weather.Location + ": " + message
+	}
+	return message, nil
+}
+
+// forecastFormatter 今天+未来两天的最高/最低气温及天气状况
+type forecastFormatter struct{}
+
+// Format 实现Formatter接口
+func (forecastFormatter) Format(data *Data) (string, error) {
+	days := data.Weather.Days
+	if len(days) == 0 {
+		return "", fmt.Errorf("weather forecast data is empty")
+	}
+	count := 3
+	if len(days) < count {
+		count = len(days)
+	}
+	parts := make([]string, 0, count)
+	for _, day := range days[:count] {
+		part := fmt.Sprintf("%s %s/%s", day.Date, formatTempC(day.MinTempC), formatTempC(day.MaxTempC))
+		if day.Description != "" {
+			part += " " + day.Description
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " | "), nil
+}
+
+// astroFormatter 日出日落、月出月落及月相
+type astroFormatter struct{}
+
+// Format 实现Formatter接口
+func (astroFormatter) Format(data *Data) (string, error) {
+	astronomy := data.Weather.Astronomy
+	if astronomy == nil {
+		return "", fmt.Errorf("astronomy data is unavailable")
+	}
+	return fmt.Sprintf("☀️ %s/%s 🌙 %s/%s %s",
+		astronomy.Sunrise, astronomy.Sunset,
+		astronomy.Moonrise, astronomy.Moonset,
+		astronomy.MoonPhase), nil
+}
+
+// formatTempC 格式化摄氏度数值
+func formatTempC(tempC float64) string {
+	return strconv.FormatFloat(tempC, 'f', -1, 64) + "°C"
+}
+
+// formatSignedTempC 格式化摄氏度数值，非负数前显式带上+号，与wttr.in单行格式保持一致
+func formatSignedTempC(tempC float64) string {
+	value := strconv.FormatFloat(tempC, 'f', -1, 64)
+	if tempC >= 0 {
+		value = "+" + value
+	}
+	return value + "°C"
+}
+
+// formatSpeed 格式化风速数值
+func formatSpeed(speedKmph float64) string {
+	return strconv.FormatFloat(speedKmph, 'f', -1, 64)
+}
@@ -0,0 +1,264 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	providerWttr      = "wttr"       // wttr.in
+	providerOpenMeteo = "open-meteo" // Open-Meteo
+)
+
+// Provider 天气数据来源，Fetch需要将拉取结果归一化为WeatherInfo
+type Provider interface {
+	// Name 返回provider标识，用于--provider flag、WEATHER_PROVIDER环境变量及日志展示
+	Name() string
+	// Fetch 拉取指定地址的天气信息
+	Fetch(ctx context.Context, location string) (*WeatherInfo, error)
+}
+
+// providers 已注册的天气数据来源，顺序即主provider拉取失败时的回退顺序
+var providers = []Provider{
+	wttrProvider{},
+	openMeteoProvider{},
+}
+
+// getProvider 根据名称获取对应的Provider
+func getProvider(name string) (Provider, error) {
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown provider: %s", name)
+}
+
+// fetchWithFallback 使用primary拉取数据，失败（如5xx、超时、限流）时依次回退到其余已注册的provider，
+// 返回实际产出数据的provider及归一化后的天气信息
+func fetchWithFallback(ctx context.Context, primary Provider, location string) (string, *WeatherInfo, error) {
+	info, err := primary.Fetch(ctx, location)
+	if err == nil {
+		return primary.Name(), info, nil
+	}
+	lastErr := fmt.Errorf("%s: %w", primary.Name(), err)
+	for _, provider := range providers {
+		if provider.Name() == primary.Name() {
+			continue
+		}
+		info, err = provider.Fetch(ctx, location)
+		if err == nil {
+			return provider.Name(), info, nil
+		}
+		lastErr = fmt.Errorf("%s: %w", provider.Name(), err)
+	}
+	return "", nil, fmt.Errorf("all providers failed, last err: %w", lastErr)
+}
+
+// wttrProvider 基于wttr.in的Provider实现
+type wttrProvider struct{}
+
+// Name 实现Provider接口
+func (wttrProvider) Name() string {
+	return providerWttr
+}
+
+// Fetch 实现Provider接口
+func (wttrProvider) Fetch(ctx context.Context, location string) (*WeatherInfo, error) {
+	// 构造url，使用PathEscape编码location，防止意外参数
+	rawURL := "https://wttr.in/" + url.PathEscape(location)
+
+	// 超时时间
+	requestCtx, requestCancel := context.WithTimeout(ctx, timeout)
+	defer requestCancel()
+
+	// 创建请求
+	req, err := http.NewRequestWithContext(requestCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed, rawURL:%s, err:%w", rawURL, err)
+	}
+
+	// 添加请求参数
+	query := req.URL.Query()
+	// 语言
+	query.Add("lang", "zh-cn")
+	// 目标数据格式
+	query.Add("format", "j1")
+	// 写回请求参数
+	req.URL.RawQuery = query.Encode()
+
+	// 发送请求
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request failed, url:%s, err:%w", req.URL.String(), err)
+	}
+	// 退出前关闭body
+	defer rsp.Body.Close()
+	// 判断http状态码
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response status code invalid, status: %s, code:%d", rsp.Status, rsp.StatusCode)
+	}
+
+	// 读取响应
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body failed: %w", err)
+	}
+
+	// 解析响应数据
+	wttrRsp := &WttrRsp{}
+	err = json.Unmarshal(body, wttrRsp)
+	if err != nil {
+		return nil, fmt.Errorf("parse response body failed: %w", err)
+	}
+
+	return wttrRsp.ToWeatherInfo(location)
+}
+
+// openMeteoGeocodeRsp Open-Meteo地理编码接口响应数据
+type openMeteoGeocodeRsp struct {
+	Results []struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"results"`
+}
+
+// openMeteoForecastRsp Open-Meteo天气预报接口响应数据
+type openMeteoForecastRsp struct {
+	CurrentWeather struct {
+		Temperature   float64 `json:"temperature"`
+		Windspeed     float64 `json:"windspeed"`
+		Winddirection float64 `json:"winddirection"`
+		Weathercode   int     `json:"weathercode"`
+	} `json:"current_weather"`
+}
+
+// openMeteoWeatherDesc 常见WMO天气代码到中文描述的映射，参考：https://open-meteo.com/en/docs
+var openMeteoWeatherDesc = map[int]string{
+	0:  "晴",
+	1:  "大致晴朗",
+	2:  "局部多云",
+	3:  "阴",
+	45: "雾",
+	48: "雾凇",
+	51: "小毛毛雨",
+	61: "小雨",
+	63: "中雨",
+	65: "大雨",
+	71: "小雪",
+	73: "中雪",
+	75: "大雪",
+	80: "阵雨",
+	95: "雷阵雨",
+}
+
+// openMeteoProvider 基于Open-Meteo（免费，无需API key）的Provider实现
+type openMeteoProvider struct{}
+
+// Name 实现Provider接口
+func (openMeteoProvider) Name() string {
+	return providerOpenMeteo
+}
+
+// Fetch 实现Provider接口，先地理编码获取经纬度，再拉取当前天气
+func (p openMeteoProvider) Fetch(ctx context.Context, location string) (*WeatherInfo, error) {
+	latitude, longitude, err := p.geocode(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	return p.forecast(ctx, location, latitude, longitude)
+}
+
+// geocode 调用Open-Meteo地理编码接口，将地址名称转换为经纬度
+func (openMeteoProvider) geocode(ctx context.Context, location string) (latitude, longitude float64, err error) {
+	rawURL := "https://geocoding-api.open-meteo.com/v1/search"
+
+	requestCtx, requestCancel := context.WithTimeout(ctx, timeout)
+	defer requestCancel()
+
+	req, err := http.NewRequestWithContext(requestCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("create geocode request failed, rawURL:%s, err:%w", rawURL, err)
+	}
+
+	query := req.URL.Query()
+	query.Add("name", location)
+	query.Add("count", "1")
+	req.URL.RawQuery = query.Encode()
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("send geocode request failed, url:%s, err:%w", req.URL.String(), err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("geocode response status code invalid, status: %s, code:%d", rsp.Status, rsp.StatusCode)
+	}
+
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return 0, 0, fmt.Errorf("read geocode response body failed: %w", err)
+	}
+
+	geocodeRsp := &openMeteoGeocodeRsp{}
+	err = json.Unmarshal(body, geocodeRsp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse geocode response body failed: %w", err)
+	}
+	if len(geocodeRsp.Results) == 0 {
+		return 0, 0, fmt.Errorf("location not found: %s", location)
+	}
+
+	return geocodeRsp.Results[0].Latitude, geocodeRsp.Results[0].Longitude, nil
+}
+
+// forecast 调用Open-Meteo天气预报接口，拉取指定经纬度的当前天气并归一化为WeatherInfo
+func (openMeteoProvider) forecast(ctx context.Context, location string, latitude, longitude float64) (*WeatherInfo, error) {
+	rawURL := "https://api.open-meteo.com/v1/forecast"
+
+	requestCtx, requestCancel := context.WithTimeout(ctx, timeout)
+	defer requestCancel()
+
+	req, err := http.NewRequestWithContext(requestCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create forecast request failed, rawURL:%s, err:%w", rawURL, err)
+	}
+
+	query := req.URL.Query()
+	query.Add("latitude", strconv.FormatFloat(latitude, 'f', -1, 64))
+	query.Add("longitude", strconv.FormatFloat(longitude, 'f', -1, 64))
+	query.Add("current_weather", "true")
+	req.URL.RawQuery = query.Encode()
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send forecast request failed, url:%s, err:%w", req.URL.String(), err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("forecast response status code invalid, status: %s, code:%d", rsp.Status, rsp.StatusCode)
+	}
+
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read forecast response body failed: %w", err)
+	}
+
+	forecastRsp := &openMeteoForecastRsp{}
+	err = json.Unmarshal(body, forecastRsp)
+	if err != nil {
+		return nil, fmt.Errorf("parse forecast response body failed: %w", err)
+	}
+
+	return &WeatherInfo{
+		Location:      location,
+		TempC:         forecastRsp.CurrentWeather.Temperature,
+		Description:   openMeteoWeatherDesc[forecastRsp.CurrentWeather.Weathercode],
+		WindspeedKmph: forecastRsp.CurrentWeather.Windspeed,
+	}, nil
+}
@@ -0,0 +1,236 @@
+package weather
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// LangValue 多语言描述的单个取值，对应wttr.in j1格式中形如[{"value": "..."}]的结构
+type LangValue struct {
+	Value string `json:"value"`
+}
+
+// LangValues 多语言描述列表
+type LangValues []LangValue
+
+// GetFirst 获取第一个描述值，不存在时返回空字符串
+func (l LangValues) GetFirst() string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0].Value
+}
+
+// CurrentCondition 当前天气状况
+type CurrentCondition struct {
+	TempC          string     `json:"temp_C"`
+	WeatherDesc    LangValues `json:"weatherDesc"`
+	LangCN         LangValues `json:"lang_zh_cn"`
+	WindspeedKmph  string     `json:"windspeedKmph"`
+	Winddir16Point string     `json:"winddir16Point"`
+}
+
+// Astronomy 日月升降等天文信息
+type Astronomy struct {
+	Sunrise          string `json:"sunrise"`
+	Sunset           string `json:"sunset"`
+	Moonrise         string `json:"moonrise"`
+	Moonset          string `json:"moonset"`
+	MoonPhase        string `json:"moon_phase"`
+	MoonIllumination string `json:"moon_illumination"`
+}
+
+// Hourly 某一天内某个时间点的天气状况
+type Hourly struct {
+	TimeStr        string     `json:"time"`
+	TempC          string     `json:"tempC"`
+	WeatherDesc    LangValues `json:"weatherDesc"`
+	LangCN         LangValues `json:"lang_zh_cn"`
+	WindspeedKmph  string     `json:"windspeedKmph"`
+	Winddir16Point string     `json:"winddir16Point"`
+}
+
+// Weather 某一天的天气状况，包含当天的逐时数据和天文信息
+type Weather struct {
+	Date      string      `json:"date"`
+	MaxtempC  string      `json:"maxtempC"`
+	MintempC  string      `json:"mintempC"`
+	Astronomy []Astronomy `json:"astronomy"`
+	Hourly    []Hourly    `json:"hourly"`
+}
+
+// NearestArea 最近地区信息
+type NearestArea struct {
+	AreaName LangValues `json:"areaName"`
+	Country  LangValues `json:"country"`
+	Region   LangValues `json:"region"`
+}
+
+// WttrRsp wttr.in j1格式响应数据，参考：https://github.com/chubin/wttr.in
+type WttrRsp struct {
+	CurrentCondition []CurrentCondition `json:"current_condition"`
+	Weather          []Weather          `json:"weather"`
+	NearestArea      []NearestArea      `json:"nearest_area"`
+}
+
+// Data 存储到本地文件中的数据
+type Data struct {
+	Weather           *WeatherInfo `json:"weather"`             // 归一化后的天气信息
+	Provider          string       `json:"provider"`            // 产出Weather的数据来源
+	UpdateTime        int64        `json:"update_time"`         // 数据更新时间
+	FetchErrorTime    int64        `json:"fetch_error_time"`    // 拉取数据发生错误的时间
+	FetchErrorMessage string       `json:"fetch_error_message"` // 拉取数据发生错误的信息
+}
+
+// DayInfo 归一化后的单日天气状况
+type DayInfo struct {
+	Date        string  `json:"date"`
+	MaxTempC    float64 `json:"max_temp_c"`
+	MinTempC    float64 `json:"min_temp_c"`
+	Description string  `json:"description"`
+}
+
+// AstronomyInfo 归一化后的日月升降等天文信息
+type AstronomyInfo struct {
+	Sunrise   string `json:"sunrise"`
+	Sunset    string `json:"sunset"`
+	Moonrise  string `json:"moonrise"`
+	Moonset   string `json:"moonset"`
+	MoonPhase string `json:"moon_phase"`
+}
+
+// WeatherInfo 归一化后的天气信息，所有Provider的拉取结果都转换为该结构，
+// 使得Formatter不必关心数据具体来自哪个上游
+type WeatherInfo struct {
+	Location       string         `json:"location"`
+	TempC          float64        `json:"temp_c"`
+	Description    string         `json:"description"`
+	WindspeedKmph  float64        `json:"windspeed_kmph"`
+	Winddir16Point string         `json:"winddir_16_point"`
+	Days           []DayInfo      `json:"days,omitempty"`
+	Astronomy      *AstronomyInfo `json:"astronomy,omitempty"`
+}
+
+// parseFloat 解析wttr.in返回的数字字符串，用于可能缺失的非必填字段（如某天预报数据缺失），
+// 空字符串视为0；必填字段请使用parseRequiredFloat
+func parseFloat(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse float failed, value:%s, err:%w", value, err)
+	}
+	return result, nil
+}
+
+// parseRequiredFloat 解析必填的数字字符串，字段为空说明上游响应异常，返回错误而不是静默置0
+func parseRequiredFloat(field, value string) (float64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("%s is empty", field)
+	}
+	return parseFloat(value)
+}
+
+// rollupDay 将某一天的Hourly数据聚合为DayInfo，description取当天正午前后的描述
+func rollupDay(weather Weather) (DayInfo, error) {
+	maxTempC, err := parseFloat(weather.MaxtempC)
+	if err != nil {
+		return DayInfo{}, err
+	}
+	minTempC, err := parseFloat(weather.MintempC)
+	if err != nil {
+		return DayInfo{}, err
+	}
+	day := DayInfo{
+		Date:     weather.Date,
+		MaxTempC: maxTempC,
+		MinTempC: minTempC,
+	}
+	// 最高/最低气温由当天各逐时记录的温度聚合得出，没有可用的逐时温度时保留当天的maxtempC/mintempC
+	hasHourlyTemp := false
+	for _, hourly := range weather.Hourly {
+		tempC, err := strconv.ParseFloat(hourly.TempC, 64)
+		if err != nil {
+			continue
+		}
+		if !hasHourlyTemp || tempC > day.MaxTempC {
+			day.MaxTempC = tempC
+		}
+		if !hasHourlyTemp || tempC < day.MinTempC {
+			day.MinTempC = tempC
+		}
+		hasHourlyTemp = true
+	}
+	// 取最接近正午的一条记录的描述作为当天代表天气
+	var closest *Hourly
+	var closestDiff int
+	for i := range weather.Hourly {
+		hourly := &weather.Hourly[i]
+		timeVal, err := strconv.Atoi(hourly.TimeStr)
+		if err != nil {
+			continue
+		}
+		diff := timeVal - 1200
+		if diff < 0 {
+			diff = -diff
+		}
+		if closest == nil || diff < closestDiff {
+			closest = hourly
+			closestDiff = diff
+		}
+	}
+	if closest != nil {
+		description := closest.LangCN.GetFirst()
+		if description == "" {
+			description = closest.WeatherDesc.GetFirst()
+		}
+		day.Description = description
+	}
+	return day, nil
+}
+
+// ToWeatherInfo 将wttr.in原始响应归一化为WeatherInfo
+func (w *WttrRsp) ToWeatherInfo(location string) (*WeatherInfo, error) {
+	if len(w.CurrentCondition) != 1 {
+		return nil, fmt.Errorf("invalid current condition length: %d", len(w.CurrentCondition))
+	}
+	current := w.CurrentCondition[0]
+	tempC, err := parseRequiredFloat("current TempC", current.TempC)
+	if err != nil {
+		return nil, err
+	}
+	windspeedKmph, err := parseFloat(current.WindspeedKmph)
+	if err != nil {
+		return nil, err
+	}
+	description := current.LangCN.GetFirst()
+	if description == "" {
+		description = current.WeatherDesc.GetFirst()
+	}
+	info := &WeatherInfo{
+		Location:       location,
+		TempC:          tempC,
+		Description:    description,
+		WindspeedKmph:  windspeedKmph,
+		Winddir16Point: current.Winddir16Point,
+	}
+	for _, day := range w.Weather {
+		dayInfo, err := rollupDay(day)
+		if err != nil {
+			return nil, err
+		}
+		info.Days = append(info.Days, dayInfo)
+	}
+	if len(w.Weather) > 0 && len(w.Weather[0].Astronomy) == 1 {
+		astronomy := w.Weather[0].Astronomy[0]
+		info.Astronomy = &AstronomyInfo{
+			Sunrise:   astronomy.Sunrise,
+			Sunset:    astronomy.Sunset,
+			Moonrise:  astronomy.Moonrise,
+			Moonset:   astronomy.Moonset,
+			MoonPhase: astronomy.MoonPhase,
+		}
+	}
+	return info, nil
+}
@@ -1,48 +1,88 @@
-// Package weather 通过wttr.in获取当前天气信息命令
+// Package weather 获取当前天气信息命令，支持wttr.in、Open-Meteo等多个数据来源
 package weather
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
-	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli/v2"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
-	filename         = "tmux-weather-go.tmp" // 存储数据的文件名称
-	validPeriod      = 10 * time.Minute      // 数据有效时间
-	errorValidPeriod = 15 * time.Second      // 错误有效时间
-	timeout          = 5 * time.Second       // 超时时间
+	defaultTTL       = 10 * time.Minute // 默认数据有效时间
+	errorValidPeriod = 15 * time.Second // 错误有效时间，期间内不重复拉取
+	timeout          = 5 * time.Second  // 单次请求超时时间
+	groupTimeout     = 10 * time.Second // 并发拉取所有地址的整体超时时间
 )
 
-// 目标地址信息，可以是城市或地址名称，具体参考wttr.in官方文档：https://github.com/chubin/wttr.in
-var location string
+// locations 目标地址信息，可以是城市或地址名称，支持重复指定或以逗号分隔传入多个，
+// 具体参考wttr.in官方文档：https://github.com/chubin/wttr.in
+var locations cli.StringSlice
+
+// format 输出格式，取值参考formatters
+var format string
+
+// provider 天气数据来源，取值参考providers
+var provider string
+
+// ttl 数据有效时间，通过time.ParseDuration解析
+var ttl time.Duration
+
+// refresh 是否跳过缓存强制拉取最新数据
+var refresh bool
 
 // command 命令实例
 var command = &cli.Command{
 	Name:    "weather",
 	Aliases: []string{"w"},
-	Usage:   "Get current weather information by wttr.in",
+	Usage:   "Get current weather information",
 	Action:  action,
 	Flags: []cli.Flag{
-		&cli.StringFlag{
+		&cli.StringSliceFlag{
 			Name:    "location",
 			Aliases: []string{"l"},
-			Usage: "Target address information, which can be a city or address name. " +
+			Usage: "Target address information, which can be a city or address name, can be repeated or " +
+				"comma-separated to fetch multiple locations concurrently. " +
 				"For details, please refer to the official document of wttr.in: " +
 				"https://github.com/chubin/wttr.in",
-			Value:       "Shenzhen",
-			Destination: &location,
+			Value:       cli.NewStringSlice("Shenzhen"),
+			Destination: &locations,
 			DefaultText: "Shenzhen",
 		},
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage: "Output format, one of: current (temp + description), oneline (compact single line), " +
+				"forecast (today and next 2 days min/max/condition), astro (sunrise/sunset/moonrise/moon phase)",
+			Value:       formatCurrent,
+			Destination: &format,
+			DefaultText: formatCurrent,
+		},
+		&cli.StringFlag{
+			Name:        "provider",
+			Aliases:     []string{"p"},
+			Usage:       "Weather data source, one of: wttr (wttr.in), open-meteo (Open-Meteo, no API key required)",
+			EnvVars:     []string{"WEATHER_PROVIDER"},
+			Value:       providerWttr,
+			Destination: &provider,
+			DefaultText: providerWttr,
+		},
+		&cli.DurationFlag{
+			Name:        "ttl",
+			Usage:       "Cache freshness duration before a location is refetched, e.g. 5m, 1h",
+			Value:       defaultTTL,
+			Destination: &ttl,
+			DefaultText: "10m",
+		},
+		&cli.BoolFlag{
+			Name:        "refresh",
+			Usage:       "Bypass the cache and force a fresh fetch for every location",
+			Destination: &refresh,
+		},
 	},
 }
 
@@ -51,167 +91,185 @@ func Command() *cli.Command {
 	return command
 }
 
+// locationResult 单个地址的处理结果
+type locationResult struct {
+	location string // 地址名称
+	message  string // 格式化后的展示消息
+	err      error  // 处理过程中发生的错误
+}
+
 // action 执行函数
 func action(cCtx *cli.Context) error {
-	// 当前时间
-	now := time.Now()
-	// 构造临时文件路径
-	name := filepath.Join(os.TempDir(), filename)
-	// 判断是否需要拉取数据
-	var needFetchData bool
-	// 读取文件内容
-	file, err := os.ReadFile(name)
-	// 如果发生错误并且错误不是文件不存在，则需要返回错误
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("read file failed, filename:%s, err:%w", name, err)
+	// 解析目标地址列表，支持重复指定及逗号分隔
+	locs := parseLocations(locations.Value())
+	if len(locs) == 0 {
+		return errors.New("no location specified")
 	}
-	// 如果文件内容不为空则进行解析并判断是否需要拉取数据
-	data := &Data{}
-	if len(file) > 0 {
-		// 解析文件内容，失败则直接返回错误
-		err = json.Unmarshal(file, data)
-		if err != nil {
-			return fmt.Errorf("unmarshal file data failed, filename:%s, err:%w", name, err)
-		}
-		// 超过数据有效时间或超过错误有效时间则需要拉取数据
-		// 优先判断错误有效时间，其次再判断是否超过数据有效期
-		if data.FetchErrorTime > 0 {
-			needFetchData = now.Sub(time.Unix(data.FetchErrorTime, 0)) > errorValidPeriod
-			// 存在错误但不用拉取数据，则直接返回错误
-			if !needFetchData {
-				return errors.New(data.FetchErrorMessage)
-			}
-		} else {
-			needFetchData = now.Sub(time.Unix(data.UpdateTime, 0)) > validPeriod
-		}
-	} else {
-		// 文件内容为空，需要拉取数据
-		needFetchData = true
-	}
-	// 如果需要拉取数据，则进行拉取
-	if needFetchData {
-		// 拉取数据
-		rsp, err := fetchData(cCtx.Context)
-		if err != nil {
-			// 发生错误时记录错误时间和错误信息
-			data.FetchErrorTime = now.Unix()
-			data.FetchErrorMessage = err.Error()
-			// 写入文件
-			saveFileErr := saveFile(name, data)
-			if saveFileErr != nil {
-				return saveFileErr
-			}
-			return err
-		}
-		// 未发生错误更新数据和拉取时间
-		data.SourceRsp = rsp
-		// 更新拉取时间
-		data.UpdateTime = time.Now().Unix()
-		// 重写错误时间和信息
-		data.FetchErrorTime = 0
-		data.FetchErrorMessage = ""
-	}
-	// 构造输出数据
-	message, err := generateMessage(data)
+	// 根据--provider选择主数据来源
+	primary, err := getProvider(provider)
 	if err != nil {
 		return err
 	}
-	// 保存数据
-	err = saveFile(name, data)
+	// 根据--format选择对应的格式化器
+	formatter, err := getFormatter(format)
 	if err != nil {
 		return err
 	}
-	// 输出数据
-	fmt.Println(message)
-	return nil
-}
 
-// fetchData 拉取数据
-func fetchData(ctx context.Context) (*WttrRsp, error) {
-	// 构造url，使用PathEscape编码location，防止意外参数
-	rawURL := "https://wttr.in/" + url.PathEscape(location)
+	// 并发拉取所有地址，整体共享一个超时时间
+	ctx, cancel := context.WithTimeout(cCtx.Context, groupTimeout)
+	defer cancel()
+	results := make([]locationResult, len(locs))
+	var g errgroup.Group
+	for i, loc := range locs {
+		i, loc := i, loc
+		g.Go(func() error {
+			// 单个地址的处理结果独立记录，互不影响，因此这里始终返回nil
+			results[i] = processLocation(ctx, primary, formatter, loc)
+			return nil
+		})
+	}
+	_ = g.Wait()
 
-	// 超时时间
-	requestCtx, requestCancel := context.WithTimeout(ctx, timeout)
-	defer requestCancel()
+	// 如果全部地址均处理失败，则直接返回错误
+	if allFailed(results) {
+		return fmt.Errorf("all locations failed, last err: %w", results[len(results)-1].err)
+	}
 
-	// 创建请求
-	req, err := http.NewRequestWithContext(requestCtx, http.MethodGet, rawURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request failed, rawURL:%s, err:%w", rawURL, err)
+	// 输出数据，成功的地址展示格式化结果，失败的地址展示简短错误标记
+	fmt.Println(joinResults(results))
+	return nil
+}
+
+// parseLocations 将--location的原始取值展开为去除空白后的地址列表，
+// 每一项都可能是以逗号分隔的多个地址
+func parseLocations(raw []string) []string {
+	var locs []string
+	for _, item := range raw {
+		for _, part := range strings.Split(item, ",") {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				locs = append(locs, part)
+			}
+		}
 	}
+	return locs
+}
 
-	// 添加请求参数
-	query := req.URL.Query()
-	// 语言
-	query.Add("lang", "zh-cn")
-	// 目标数据格式
-	query.Add("format", "j1")
-	// 写回请求参数
-	req.URL.RawQuery = query.Encode()
-
-	// 发送请求
-	rsp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("send request failed, url:%s, err:%w", req.URL.String(), err)
+// allFailed 判断是否所有地址均处理失败
+func allFailed(results []locationResult) bool {
+	for _, result := range results {
+		if result.err == nil {
+			return false
+		}
 	}
-	// 退出前关闭body
-	defer rsp.Body.Close()
-	// 判断http状态码
-	if rsp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("response status code invalid, status: %s, code:%d", rsp.Status, rsp.StatusCode)
+	return true
+}
+
+// joinResults 将各地址的处理结果拼接为一行展示消息，失败的地址以⚠标记
+func joinResults(results []locationResult) string {
+	parts := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.err != nil {
+			parts = append(parts, fmt.Sprintf("%s ⚠", result.location))
+			continue
+		}
+		parts = append(parts, result.message)
 	}
+	return strings.Join(parts, " | ")
+}
 
-	// 读取响应
-	body, err := io.ReadAll(rsp.Body)
+// processLocation 处理单个地址：读取本地缓存，按需拉取数据，拉取失败时在有旧数据的情况下回退展示旧数据
+func processLocation(ctx context.Context, primary Provider, formatter Formatter, loc string) locationResult {
+	result := locationResult{location: loc}
+
+	// 当前时间
+	now := time.Now()
+	// 读取该地址对应的缓存数据
+	data, err := loadCache(loc)
 	if err != nil {
-		return nil, fmt.Errorf("read response body failed: %w", err)
+		result.err = err
+		return result
+	}
+	if data == nil {
+		data = &Data{}
 	}
 
-	// 解析响应数据
-	wttrRsp := &WttrRsp{}
-	err = json.Unmarshal(body, wttrRsp)
-	if err != nil {
-		return nil, fmt.Errorf("parse response body failed: %w", err)
+	// 判断是否需要拉取数据：--refresh强制拉取；刚发生过错误则在错误有效期内不重复拉取，
+	// 错误有效期一过应独立于--ttl立即重试，不必等到整个--ttl窗口都过期；
+	// 否则（未发生过错误）根据--ttl判断数据是否已过期
+	needFetchData := refresh
+	if !needFetchData {
+		switch {
+		case data.FetchErrorTime > 0 && now.Sub(time.Unix(data.FetchErrorTime, 0)) <= errorValidPeriod:
+			needFetchData = false
+		case data.FetchErrorTime > 0:
+			needFetchData = true
+		case data.Weather == nil:
+			needFetchData = true
+		default:
+			needFetchData = now.Sub(time.Unix(data.UpdateTime, 0)) > ttl
+		}
 	}
 
-	return wttrRsp, nil
-}
+	// 是否需要在展示消息前附加"数据已过期"的提示
+	stale := false
 
-// generateMessage 构造输出消息
-func generateMessage(data *Data) (string, error) {
-	// 当前数据
-	if len(data.SourceRsp.CurrentCondition) != 1 {
-		return "", fmt.Errorf("invalid current condition length: %d", len(data.SourceRsp.CurrentCondition))
+	if needFetchData {
+		// 拉取数据，主provider失败时自动回退到其余已注册的provider
+		usedProvider, info, fetchErr := fetchWithFallback(ctx, primary, loc)
+		if fetchErr != nil {
+			// 发生错误时记录错误时间和错误信息
+			data.FetchErrorTime = now.Unix()
+			data.FetchErrorMessage = fetchErr.Error()
+			// 没有可用的旧数据，只能将错误透出；写缓存失败与否都不影响这个结论
+			if data.Weather == nil {
+				_ = saveCache(loc, data)
+				result.err = fetchErr
+				return result
+			}
+			// 存在旧数据，容忍本次拉取失败，回退展示旧数据；写缓存失败不应阻断这次回退展示
+			_ = saveCache(loc, data)
+			stale = true
+		} else {
+			// 未发生错误更新数据和拉取时间
+			data.Weather = info
+			data.Provider = usedProvider
+			data.UpdateTime = now.Unix()
+			// 重写错误时间和信息
+			data.FetchErrorTime = 0
+			data.FetchErrorMessage = ""
+			if err := saveCache(loc, data); err != nil {
+				result.err = err
+				return result
+			}
+		}
+	} else if data.Weather == nil {
+		// 既不需要拉取（错误有效期内）又没有旧数据可用，直接透出之前记录的错误
+		result.err = errors.New(data.FetchErrorMessage)
+		return result
+	} else if data.FetchErrorTime > 0 {
+		// 处于错误有效期内且存在旧数据，同样视为过期数据
+		stale = true
 	}
-	current := data.SourceRsp.CurrentCondition[0]
-	// 当前气温
-	if current.TempC == "" {
-		return "", fmt.Errorf("current TempC is empty")
+
+	// 构造输出数据
+	message, err := formatter.Format(data)
+	if err != nil {
+		result.err = err
+		return result
 	}
-	// 构造输出消息
-	message := current.TempC + "°C"
-	// 当前天气状态中文描述
-	description := current.LangCN.GetFirst()
-	if description != "" {
-		message = description + " " + message
+	if stale {
+		message = fmt.Sprintf("⚠(%s ago) %s", formatAge(now.Sub(time.Unix(data.UpdateTime, 0))), message)
 	}
-
-	// 返回结果
-	return message, nil
+	result.message = message
+	return result
 }
 
-// saveFile 保存数据到文件
-func saveFile(name string, data *Data) error {
-	// 序列化数据
-	file, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("marshal data failed: %w", err)
-	}
-	// 写入文件内容
-	err = os.WriteFile(name, file, 0644)
-	if err != nil {
-		return fmt.Errorf("write file failed, filename:%s, err:%w", name, err)
+// formatAge 将时间间隔格式化为便于阅读的字符串，不足一分钟精确到秒，否则精确到分钟，例如"45s"、"5m0s"、"2h0m0s"
+func formatAge(age time.Duration) string {
+	if age < time.Minute {
+		return age.Round(time.Second).String()
 	}
-	return nil
+	return age.Round(time.Minute).String()
 }